@@ -2,10 +2,8 @@ package client
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha1"
 	"crypto/tls"
-	"encoding/base64"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"golang.org/x/net/context"
@@ -13,7 +11,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +26,15 @@ type Client struct {
 	secretKey   string        // Required
 	insecure    bool          // Optional
 	proxyUrl    string        // Optional
+	retryPolicy *RetryPolicy  // Optional
+	signer      Signer        // Optional, defaults to HMACSHA1Signer
+
+	clientCert    *tls.Certificate // Optional, enables mTLS
+	rootCAs       *x509.CertPool   // Optional, custom CA bundle
+	tlsMinVersion uint16           // Optional, defaults to tls.VersionTLS11
+	tlsMaxVersion uint16           // Optional, defaults to tls.VersionTLS13
+
+	metricsSink MetricsSink // Optional
 }
 
 //singleton implementation of a client
@@ -66,6 +72,9 @@ func initClient(apiKey, secretKey string, options ...Option) *Client {
 	for _, option := range options {
 		option(client)
 	}
+	if client.signer == nil {
+		client.signer = &HMACSHA1Signer{APIKey: apiKey, SecretKey: secretKey}
+	}
 
 	//Setting up the HTTP client for the API call
 	var transport *http.Transport
@@ -73,8 +82,12 @@ func initClient(apiKey, secretKey string, options ...Option) *Client {
 	if client.proxyUrl != "" {
 		transport = client.configProxy(transport)
 	}
+	var roundTripper http.RoundTripper = transport
+	if client.metricsSink != nil {
+		roundTripper = instrument(transport, client.metricsSink)
+	}
 	client.httpclient = &http.Client{
-		Transport: transport,
+		Transport: roundTripper,
 	}
 	return client
 }
@@ -86,20 +99,34 @@ func GetClient(apiKey, secretKey string, options ...Option) *Client {
 }
 
 func (c *Client) useInsecureHTTPClient(insecure bool) *http.Transport {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			CipherSuites: []uint16{
-				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-			},
-			PreferServerCipherSuites: true,
-			InsecureSkipVerify:       insecure,
-			MinVersion:               tls.VersionTLS11,
-			MaxVersion:               tls.VersionTLS12,
+	tlsConfig := &tls.Config{
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 		},
+		PreferServerCipherSuites: true,
+		InsecureSkipVerify:       insecure,
+		MinVersion:               tls.VersionTLS11,
+		MaxVersion:               tls.VersionTLS13,
+	}
+	if c.tlsMinVersion != 0 {
+		tlsConfig.MinVersion = c.tlsMinVersion
+	}
+	if c.tlsMaxVersion != 0 {
+		tlsConfig.MaxVersion = c.tlsMaxVersion
+	}
+	if c.rootCAs != nil {
+		tlsConfig.RootCAs = c.rootCAs
+	}
+	if c.clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*c.clientCert}
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
 	}
 
 	return transport
@@ -114,42 +141,33 @@ func (c *Client) configProxy(transport *http.Transport) *http.Transport {
 	return transport
 }
 
-func getToken(apiKey, secretKey string) string {
-	// Extracts epoch time in milliseconds
-	epochTime := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
-
-	// Calculate hmac using secret key and epoch time
-	h := hmac.New(sha1.New, []byte(secretKey))
-	h.Write([]byte(epochTime))
-	sha := base64.StdEncoding.EncodeToString(h.Sum(nil))
-
-	// Building token as 'apikey:hmac:time'
-	token := apiKey + ":" + sha + ":" + epochTime
-	return token
-}
-
-func (c *Client) makeRequest(method, endpoint string, payload []byte) (*http.Request, error) {
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload []byte) (*http.Request, error) {
 	//Defining http request
 	var req *http.Request
 	var err error
 	if method == "POST" || method == "PUT" {
-		req, err = http.NewRequest(method, endpoint, bytes.NewBuffer(payload))
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(payload))
 	} else {
-		req, err = http.NewRequest(method, endpoint, nil)
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, nil)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	//Calling for token and setting headers
-	token := getToken(c.apiKey, c.secretKey)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-cns-security-token", token)
+	if err := c.signer.Sign(req); err != nil {
+		return nil, err
+	}
 
 	return req, nil
 }
 
+// Save is equivalent to SaveContext with context.Background().
 func (c *Client) Save(obj interface{}, endpoint string) (responce *http.Response, err error) {
+	return c.SaveContext(context.Background(), obj, endpoint)
+}
+
+func (c *Client) SaveContext(ctx context.Context, obj interface{}, endpoint string) (responce *http.Response, err error) {
 	jsonPayload, err := json.Marshal(obj)
 	if err != nil {
 		log.Fatal(err)
@@ -165,23 +183,13 @@ func (c *Client) Save(obj interface{}, endpoint string) (responce *http.Response
 		url = fmt.Sprintf("%s%s", BaseURL, endpoint)
 	}
 
-	req, err1 := c.makeRequest("POST", url, jsonPayload)
-	log.Println(req)
-	if err1 != nil {
-		return nil, err1
-	}
-
-	ctx := context.Background()
-	err = c.rateLimiter.Wait(ctx) // This is a blocking call. Honors the rate limit
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return c.makeRequest(ctx, "POST", url, jsonPayload)
+	})
+	log.Println(resp)
 	if err != nil {
-		return nil, err
+		return resp, err
 	}
-
-	resp, err2 := c.httpclient.Do(req)
-	if err2 != nil {
-		return nil, err2
-	}
-	log.Println(resp)
 	if flag == false {
 		return resp, checkForErrors(resp)
 	}
@@ -191,6 +199,7 @@ func (c *Client) Save(obj interface{}, endpoint string) (responce *http.Response
 func checkForErrors(resp *http.Response) error {
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -215,6 +224,7 @@ func checkForErrors(resp *http.Response) error {
 func checkForErrorsChecks(resp *http.Response) error {
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != 201 && resp.StatusCode != 202 {
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -225,7 +235,12 @@ func checkForErrorsChecks(resp *http.Response) error {
 	return nil
 }
 
+// GetbyId is equivalent to GetbyIdContext with context.Background().
 func (c *Client) GetbyId(endpoint string) (response *http.Response, err error) {
+	return c.GetbyIdContext(context.Background(), endpoint)
+}
+
+func (c *Client) GetbyIdContext(ctx context.Context, endpoint string) (response *http.Response, err error) {
 	var url string
 	var flag bool
 	urlArr := strings.Split(endpoint, "/")
@@ -236,33 +251,25 @@ func (c *Client) GetbyId(endpoint string) (response *http.Response, err error) {
 		url = fmt.Sprintf("%s%s", BaseURL, endpoint)
 	}
 
-	req, err := c.makeRequest("GET", url, nil)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return c.makeRequest(ctx, "GET", url, nil)
+	})
+	log.Println("Response for Get: ", resp)
 	if err != nil {
-		return nil, err
+		return resp, err
 	}
-	log.Println("In GET by ID :", req)
-
-	if c.rateLimiter != nil {
-		ctx := context.Background()
-		err = c.rateLimiter.Wait(ctx) // This is a blocking call. Honors the rate limit
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	resp, err1 := c.httpclient.Do(req)
-	if err1 != nil {
-		return nil, err1
-	}
-
-	log.Println("Response for Get: ", resp)
 	if flag == false {
 		return resp, checkForErrors(resp)
 	}
 	return resp, checkForErrorsChecks(resp)
 }
 
+// DeletebyId is equivalent to DeletebyIdContext with context.Background().
 func (c *Client) DeletebyId(endpoint string) error {
+	return c.DeletebyIdContext(context.Background(), endpoint)
+}
+
+func (c *Client) DeletebyIdContext(ctx context.Context, endpoint string) error {
 	var url string
 	urlArr := strings.Split(endpoint, "/")
 	if len(urlArr) > 2 && urlArr[2] == "api.sonar.constellix.com" {
@@ -271,31 +278,22 @@ func (c *Client) DeletebyId(endpoint string) error {
 		url = fmt.Sprintf("%s%s", BaseURL, endpoint)
 	}
 
-	req, err := c.makeRequest("DELETE", url, nil)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return c.makeRequest(ctx, "DELETE", url, nil)
+	})
+	log.Println("Response from server for delete : ", resp)
 	if err != nil {
 		return err
 	}
-
-	log.Println("request for delete : ", req)
-
-	if c.rateLimiter != nil {
-		ctx := context.Background()
-		err = c.rateLimiter.Wait(ctx) // This is a blocking call. Honors the rate limit
-		if err != nil {
-			return err
-		}
-	}
-
-	resp, err1 := c.httpclient.Do(req)
-	if err1 != nil {
-		log.Println("Response from server for delete : ", resp)
-		return err1
-	}
-	log.Println("Response from server for delete : ", resp)
 	return checkForErrorsChecks(resp)
 }
 
+// UpdatebyID is equivalent to UpdatebyIDContext with context.Background().
 func (c *Client) UpdatebyID(obj interface{}, endpoint string) (response *http.Response, err error) {
+	return c.UpdatebyIDContext(context.Background(), obj, endpoint)
+}
+
+func (c *Client) UpdatebyIDContext(ctx context.Context, obj interface{}, endpoint string) (response *http.Response, err error) {
 	jsonPayload, err := json.Marshal(obj)
 	if err != nil {
 		log.Fatal(err)
@@ -310,25 +308,13 @@ func (c *Client) UpdatebyID(obj interface{}, endpoint string) (response *http.Re
 		url = fmt.Sprintf("%s%s", BaseURL, endpoint)
 	}
 
-	req, err1 := c.makeRequest("PUT", url, jsonPayload)
-	log.Println(req)
-	if err1 != nil {
-		return nil, err1
-	}
-
-	if c.rateLimiter != nil {
-		ctx := context.Background()
-		err = c.rateLimiter.Wait(ctx) // This is a blocking call. Honors the rate limit
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	resp, err2 := c.httpclient.Do(req)
-	if err2 != nil {
-		return nil, err2
-	}
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return c.makeRequest(ctx, "PUT", url, jsonPayload)
+	})
 	log.Println(resp)
+	if err != nil {
+		return resp, err
+	}
 	if flag == false {
 		return resp, checkForErrors(resp)
 	}
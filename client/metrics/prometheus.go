@@ -0,0 +1,43 @@
+// Package metrics provides a client.MetricsSink adapter for Prometheus-style
+// metrics, without taking a hard dependency on the prometheus client
+// library. Satisfy Counter and Histogram with prometheus.Counter and
+// prometheus.Histogram (or CounterVec/HistogramVec.WithLabelValues(...)).
+package metrics
+
+import "time"
+
+// Counter matches prometheus.Counter.
+type Counter interface {
+	Inc()
+	Add(float64)
+}
+
+// Histogram matches prometheus.Histogram.
+type Histogram interface {
+	Observe(float64)
+}
+
+// PrometheusSink adapts Counter/Histogram metrics to client.MetricsSink.
+// Any field left nil is simply skipped.
+type PrometheusSink struct {
+	RequestsTotal Counter
+	BytesInTotal  Counter
+	BytesOutTotal Counter
+	LatencySecs   Histogram
+}
+
+// ObserveRequest implements client.MetricsSink.
+func (s *PrometheusSink) ObserveRequest(method, endpoint string, status int, latency time.Duration, bytesIn, bytesOut int64) {
+	if s.RequestsTotal != nil {
+		s.RequestsTotal.Inc()
+	}
+	if s.BytesInTotal != nil {
+		s.BytesInTotal.Add(float64(bytesIn))
+	}
+	if s.BytesOutTotal != nil {
+		s.BytesOutTotal.Add(float64(bytesOut))
+	}
+	if s.LatencySecs != nil {
+		s.LatencySecs.Observe(latency.Seconds())
+	}
+}
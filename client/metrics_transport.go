@@ -0,0 +1,126 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSink receives one observation per round trip made by the client.
+type MetricsSink interface {
+	ObserveRequest(method, endpoint string, status int, latency time.Duration, bytesIn, bytesOut int64)
+}
+
+// WithMetrics instruments every request made by the client, reporting
+// method/endpoint/status, latency and byte counts to sink.
+func WithMetrics(sink MetricsSink) Option {
+	return func(client *Client) {
+		client.metricsSink = sink
+	}
+}
+
+// instrument wraps transport so that every round trip reports byte counts
+// and latency to sink.
+//
+// Bytes are counted off the request/response bodies rather than the raw
+// net.Conn: for TLS requests (the only scheme this client uses),
+// http.Transport swaps the connection for its *tls.Conn wrapper before
+// firing httptrace.ClientTrace.GotConn, so a DialContext-installed
+// countingConn can never be observed from RoundTrip. Counting at the body
+// level sidesteps that entirely and works the same for HTTP and HTTPS.
+func instrument(transport *http.Transport, sink MetricsSink) http.RoundTripper {
+	return &instrumentedRoundTripper{next: transport, sink: sink}
+}
+
+// instrumentedRoundTripper wraps an http.RoundTripper, reporting one
+// ObserveRequest call per round trip.
+type instrumentedRoundTripper struct {
+	next http.RoundTripper
+	sink MetricsSink
+}
+
+// RoundTrip reports the observation once the response body has been fully
+// read or closed, whichever happens first. http.Transport.RoundTrip returns
+// as soon as the status line and headers are parsed — the body is read
+// lazily by the caller afterward, so the byte count has to be finalized
+// from the body wrapper, not right after RoundTrip returns.
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var counted *countingReadCloser
+	if req.Body != nil {
+		counted = &countingReadCloser{ReadCloser: req.Body}
+		req.Body = counted
+	}
+	bytesOut := func() int64 {
+		if counted == nil {
+			return 0
+		}
+		return atomic.LoadInt64(&counted.n)
+	}
+
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		rt.sink.ObserveRequest(req.Method, req.URL.Path, 0, time.Since(start), 0, bytesOut())
+		return resp, err
+	}
+
+	observe := func(bytesIn int64) {
+		rt.sink.ObserveRequest(req.Method, req.URL.Path, resp.StatusCode, time.Since(start), bytesIn, bytesOut())
+	}
+
+	if resp.Body == nil {
+		observe(0)
+		return resp, nil
+	}
+
+	resp.Body = &observingBody{ReadCloser: resp.Body, onDone: func(bytesIn int64) { observe(bytesIn) }}
+	return resp, nil
+}
+
+// countingReadCloser counts the bytes read from the wrapped body as the
+// transport streams a request body to the server.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// observingBody counts bytes read from the response body and fires onDone
+// exactly once, either when the caller reads through to EOF or when the
+// body is closed — whichever happens first. A backstop on EOF matters
+// because this library's own CRUD methods hand the body of a successful
+// response to the caller to decode and do not close it themselves.
+type observingBody struct {
+	io.ReadCloser
+	onDone func(bytesIn int64)
+	once   sync.Once
+	n      int64
+}
+
+func (b *observingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.n, int64(n))
+	if err == io.EOF {
+		b.fire()
+	}
+	return n, err
+}
+
+func (b *observingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.fire()
+	return err
+}
+
+func (b *observingBody) fire() {
+	b.once.Do(func() {
+		b.onDone(atomic.LoadInt64(&b.n))
+	})
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type observation struct {
+	method, endpoint  string
+	status            int
+	bytesIn, bytesOut int64
+}
+
+type fakeSink struct {
+	mu  sync.Mutex
+	obs []observation
+}
+
+func (f *fakeSink) ObserveRequest(method, endpoint string, status int, latency time.Duration, bytesIn, bytesOut int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.obs = append(f.obs, observation{method, endpoint, status, bytesIn, bytesOut})
+}
+
+func (f *fakeSink) only(t *testing.T) observation {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.obs) != 1 {
+		t.Fatalf("got %d observations, want exactly 1: %+v", len(f.obs), f.obs)
+	}
+	return f.obs[0]
+}
+
+// TestInstrument_CountsBytesOverTLS exercises the real bug the maintainer
+// reported: httptrace.ClientTrace.GotConn can never observe a
+// DialContext-installed countingConn for HTTPS, because http.Transport
+// swaps in its *tls.Conn wrapper first. BaseURL is HTTPS-only, so this test
+// uses an httptest.NewTLSServer to make sure byte counts come out non-zero
+// over TLS, and that they fire from reading the body to EOF without the
+// caller ever calling resp.Body.Close().
+func TestInstrument_CountsBytesOverTLS(t *testing.T) {
+	const respBody = "0123456789"
+	const respRepeats = 100 // 1000 bytes
+	reqBody := "hello-request-body"
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		for i := 0; i < respRepeats; i++ {
+			_, _ = w.Write([]byte(respBody))
+		}
+	}))
+	defer srv.Close()
+
+	transport := srv.Client().Transport.(*http.Transport).Clone()
+	sink := &fakeSink{}
+	httpClient := &http.Client{Transport: instrument(transport, sink)}
+
+	resp, err := httpClient.Post(srv.URL, "application/octet-stream", strings.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	got, err := ioutil.ReadAll(resp.Body) // EOF, deliberately no resp.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(got) != len(respBody)*respRepeats {
+		t.Fatalf("read %d response bytes, want %d", len(got), len(respBody)*respRepeats)
+	}
+
+	obs := sink.only(t)
+	if obs.status != http.StatusOK {
+		t.Errorf("status = %d, want 200", obs.status)
+	}
+	if obs.bytesIn != int64(len(respBody)*respRepeats) {
+		t.Errorf("bytesIn = %d, want %d (zero would mean the GotConn bug is back)", obs.bytesIn, len(respBody)*respRepeats)
+	}
+	if obs.bytesOut != int64(len(reqBody)) {
+		t.Errorf("bytesOut = %d, want %d", obs.bytesOut, len(reqBody))
+	}
+}
+
+func TestInstrument_ReportsOnTransportError(t *testing.T) {
+	sink := &fakeSink{}
+	transport := &http.Transport{}
+	httpClient := &http.Client{Transport: instrument(transport, sink)}
+
+	// Nothing is listening on this address.
+	_, err := httpClient.Get("http://127.0.0.1:1")
+	if err == nil {
+		t.Fatal("Get() error = nil, want a dial error")
+	}
+
+	obs := sink.only(t)
+	if obs.status != 0 {
+		t.Errorf("status = %d, want 0 for a transport-level failure", obs.status)
+	}
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries failed requests.
+type RetryPolicy struct {
+	MaxAttempts int           // Total number of attempts, including the first one.
+	BaseDelay   time.Duration // Delay before the second attempt.
+	MaxDelay    time.Duration // Upper bound applied after backoff and jitter.
+	Multiplier  float64       // Backoff multiplier applied per attempt.
+	Jitter      float64       // Randomizes delay within [1-Jitter, 1+Jitter].
+
+	// RetryOn decides whether an attempt should be retried. Defaults to
+	// DefaultRetryOn when left nil.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// WithRetry enables the retry subsystem using the given policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(client *Client) {
+		client.retryPolicy = &policy
+	}
+}
+
+// DefaultRetryOn retries on network errors and on 429/502/503/504 responses.
+func DefaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryError is returned when all attempts of a retried request are
+// exhausted. It wraps the error from the last attempt.
+type RetryError struct {
+	Attempts int
+	Last     error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %v", e.Attempts, e.Last)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Last
+}
+
+// doWithRetry performs the request produced by buildReq once per attempt,
+// retrying according to c.retryPolicy. Each attempt builds a fresh
+// *http.Request via buildReq so POST/PUT bodies replay cleanly. When no
+// policy was configured via WithRetry, it performs a single attempt and
+// returns whatever the transport returned, untouched — existing callers
+// that never opted into retries must keep seeing the real response (e.g.
+// a 429 with a JSON error body) rather than a synthesized RetryError.
+func (c *Client) doWithRetry(ctx context.Context, buildReq func() (*http.Request, error)) (*http.Response, error) {
+	policy := c.retryPolicy
+	if policy == nil {
+		return c.doOnce(ctx, buildReq)
+	}
+
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = DefaultRetryOn
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts(policy); attempt++ {
+		resp, err := c.doOnce(ctx, buildReq)
+		if !retryOn(resp, err) {
+			return resp, err
+		}
+
+		lastErr = err
+		if lastErr == nil && resp != nil {
+			lastErr = fmt.Errorf("last response status %d", resp.StatusCode)
+		}
+
+		if attempt == maxAttempts(policy) {
+			drainAndClose(resp)
+			break
+		}
+
+		delay := retryDelay(policy, attempt, resp)
+		drainAndClose(resp)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, &RetryError{Attempts: maxAttempts(policy), Last: lastErr}
+}
+
+// doOnce builds and performs a single request, honoring the rate limiter.
+func (c *Client) doOnce(ctx context.Context, buildReq func() (*http.Request, error)) (*http.Response, error) {
+	req, err := buildReq()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.httpclient.Do(req)
+}
+
+func maxAttempts(policy *RetryPolicy) int {
+	if policy.MaxAttempts < 1 {
+		return 1
+	}
+	return policy.MaxAttempts
+}
+
+// retryDelay computes the delay before the next attempt, honoring a
+// Retry-After header when present and falling back to jittered exponential
+// backoff otherwise.
+func retryDelay(policy *RetryPolicy, attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp); ok {
+			return d
+		}
+	}
+
+	delay := float64(policy.BaseDelay) * math.Pow(policy.Multiplier, float64(attempt-1))
+
+	if policy.Jitter > 0 {
+		factor := 1 - policy.Jitter + rand.Float64()*2*policy.Jitter
+		delay *= factor
+	}
+
+	// Cap last, after jitter, so MaxDelay is a true upper bound as documented
+	// on RetryPolicy.MaxDelay instead of one jitter factor above it.
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	return time.Duration(delay)
+}
+
+// retryAfter parses the Retry-After header, supporting both delta-seconds
+// and HTTP-date forms.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(header); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func drainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
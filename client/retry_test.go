@@ -0,0 +1,217 @@
+package client
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+		Multiplier: 2,
+	}
+
+	cases := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{"first retry", 1, 100 * time.Millisecond},
+		{"second retry doubles", 2, 200 * time.Millisecond},
+		{"third retry doubles again", 3, 400 * time.Millisecond},
+		{"capped at MaxDelay", 10, time.Second},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := retryDelay(policy, tc.attempt, nil)
+			if got != tc.want {
+				t.Errorf("retryDelay(attempt=%d) = %v, want %v", tc.attempt, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay_MaxDelayCapsAfterJitter(t *testing.T) {
+	policy := &RetryPolicy{
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Second,
+		Multiplier: 1,
+		Jitter:     0.5,
+	}
+
+	for i := 0; i < 50; i++ {
+		got := retryDelay(policy, 1, nil)
+		if got > policy.MaxDelay {
+			t.Fatalf("retryDelay() = %v, must never exceed MaxDelay %v even with jitter", got, policy.MaxDelay)
+		}
+	}
+}
+
+func TestRetryDelay_HonorsRetryAfterHeader(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: time.Millisecond, Multiplier: 1}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"5"}}}
+
+	got := retryDelay(policy, 1, resp)
+	if got != 5*time.Second {
+		t.Errorf("retryDelay() = %v, want 5s from Retry-After", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		wantOK bool
+		want   time.Duration
+	}{
+		{"absent", "", false, 0},
+		{"delta seconds", "120", true, 120 * time.Second},
+		{"not a date or number", "garbage", false, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			got, ok := retryAfter(resp)
+			if ok != tc.wantOK {
+				t.Fatalf("retryAfter() ok = %v, want %v", ok, tc.wantOK)
+			}
+			if ok && got != tc.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// stubTransport replays canned responses/errors in order and records the
+// body sent on each request, so tests can assert on attempt count and body
+// replay without a real server.
+type stubTransport struct {
+	responses []*http.Response
+	errs      []error
+	bodies    []string
+	calls     int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := s.calls
+	s.calls++
+
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(b))
+	}
+
+	if i < len(s.errs) && s.errs[i] != nil {
+		return nil, s.errs[i]
+	}
+	if i < len(s.responses) {
+		return s.responses[i], nil
+	}
+	return s.responses[len(s.responses)-1], nil
+}
+
+func newStatusResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Header:     http.Header{},
+	}
+}
+
+func TestDoWithRetry_NoPolicyReturnsRawResponse(t *testing.T) {
+	stub := &stubTransport{responses: []*http.Response{newStatusResponse(http.StatusTooManyRequests, `{"errors":["slow down"]}`)}}
+	c := &Client{httpclient: &http.Client{Transport: stub}}
+
+	resp, err := c.doWithRetry(context.Background(), func() (*http.Request, error) {
+		return http.NewRequest("GET", "http://example.com", nil)
+	})
+	if err != nil {
+		t.Fatalf("doWithRetry() error = %v, want nil for a client that never called WithRetry", err)
+	}
+	if resp == nil {
+		t.Fatal("doWithRetry() resp = nil, want the raw 429 response")
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("resp.StatusCode = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `{"errors":["slow down"]}` {
+		t.Errorf("resp.Body = %q, want the untouched body so checkForErrors can parse it", body)
+	}
+	if stub.calls != 1 {
+		t.Errorf("calls = %d, want exactly 1 attempt without a retry policy", stub.calls)
+	}
+}
+
+func TestDoWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	stub := &stubTransport{responses: []*http.Response{
+		newStatusResponse(http.StatusServiceUnavailable, "unavailable"),
+		newStatusResponse(http.StatusServiceUnavailable, "unavailable"),
+		newStatusResponse(http.StatusServiceUnavailable, "unavailable"),
+	}}
+	c := &Client{
+		httpclient:  &http.Client{Transport: stub},
+		retryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Multiplier: 1},
+	}
+
+	_, err := c.doWithRetry(context.Background(), func() (*http.Request, error) {
+		return http.NewRequest("GET", "http://example.com", nil)
+	})
+	if stub.calls != 3 {
+		t.Fatalf("calls = %d, want 3 (MaxAttempts)", stub.calls)
+	}
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("err = %v, want a *RetryError", err)
+	}
+	if retryErr.Attempts != 3 {
+		t.Errorf("RetryError.Attempts = %d, want 3", retryErr.Attempts)
+	}
+	if retryErr.Last == nil {
+		t.Error("RetryError.Last = nil, want the last response status to be preserved for debugging")
+	}
+}
+
+func TestDoWithRetry_ReplaysBodyOnEachAttempt(t *testing.T) {
+	stub := &stubTransport{responses: []*http.Response{
+		newStatusResponse(http.StatusServiceUnavailable, ""),
+		newStatusResponse(http.StatusOK, "ok"),
+	}}
+	c := &Client{
+		httpclient:  &http.Client{Transport: stub},
+		retryPolicy: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, Multiplier: 1},
+		signer:      &HMACSHA1Signer{APIKey: "key", SecretKey: "secret"},
+	}
+
+	payload := []byte(`{"name":"example"}`)
+	resp, err := c.doWithRetry(context.Background(), func() (*http.Request, error) {
+		return c.makeRequest(context.Background(), "POST", "http://example.com", payload)
+	})
+	if err != nil {
+		t.Fatalf("doWithRetry() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("resp.StatusCode = %d, want 200 on the second attempt", resp.StatusCode)
+	}
+	if len(stub.bodies) != 2 {
+		t.Fatalf("got %d request bodies, want 2 (one per attempt)", len(stub.bodies))
+	}
+	for i, body := range stub.bodies {
+		if body != string(payload) {
+			t.Errorf("attempt %d body = %q, want %q to replay cleanly", i+1, body, payload)
+		}
+	}
+}
+
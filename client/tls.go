@@ -0,0 +1,47 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+)
+
+// ClientCertificate enables mTLS by presenting the given PEM-encoded
+// certificate/key pair to the server.
+func ClientCertificate(certPEM, keyPEM []byte) Option {
+	return func(client *Client) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			log.Fatal(err)
+		}
+		client.clientCert = &cert
+	}
+}
+
+// RootCAs trusts the given PEM-encoded CA bundle instead of the system
+// pool, for users behind a TLS-terminating proxy or private PKI.
+func RootCAs(caPEM []byte) Option {
+	return func(client *Client) {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			log.Fatal("client: failed to parse root CA bundle")
+		}
+		client.rootCAs = pool
+	}
+}
+
+// TLSMinVersion overrides the minimum TLS version negotiated with the
+// server. See the tls.VersionTLS* constants.
+func TLSMinVersion(version uint16) Option {
+	return func(client *Client) {
+		client.tlsMinVersion = version
+	}
+}
+
+// TLSMaxVersion overrides the maximum TLS version negotiated with the
+// server. See the tls.VersionTLS* constants.
+func TLSMaxVersion(version uint16) Option {
+	return func(client *Client) {
+		client.tlsMaxVersion = version
+	}
+}